@@ -17,11 +17,16 @@ package worker
 import (
 	"context"
 
-	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	extensionspredicate "github.com/gardener/gardener/extensions/pkg/predicate"
@@ -34,8 +39,6 @@ const (
 	FinalizerName = "extensions.gardener.cloud/worker"
 	// ControllerName is the name of the controller.
 	ControllerName = "worker"
-	// ControllerNameState is the name of the controller responsible for updating the worker's state.
-	ControllerNameState = "worker-state"
 )
 
 // AddArgs are arguments for adding an worker controller to a manager.
@@ -55,77 +58,110 @@ type AddArgs struct {
 	// If the annotation is not ignored, the extension controller will only reconcile
 	// with a present operation annotation typically set during a reconcile (e.g in the maintenance time) by the Gardenlet
 	IgnoreOperationAnnotation bool
+	// ExtensionClass defines the extension class to filter for. It is used to distinguish between Worker
+	// resources of the garden cluster and Worker resources of a seed cluster, so that the controller only
+	// reconciles the ones matching its class.
+	ExtensionClass extensionsv1alpha1.ExtensionClass
+	// PublishMachineDeploymentsEarly specifies whether the reconciler should publish the desired
+	// MachineDeployments to the Worker's status (together with a MachineDeploymentsLastUpdateTime) before
+	// waiting for the underlying Machines to become Ready. This allows callers such as gardenlet's
+	// cluster-autoscaler deployment step to start as soon as the MachineDeployments are known, instead of
+	// blocking until the Worker resource is Ready.
+	PublishMachineDeploymentsEarly bool
+	// ShootNodeCache is the cache used to watch Node objects in the shoot cluster. If set, the controller
+	// additionally watches Nodes and triggers a Worker reconciliation when gardener-node-agent reports a new
+	// kubernetes-version or OS-version, so that the actuator can drive an in-place update of the node.
+	// Namespace must be set together with it.
+	ShootNodeCache cache.Cache
+	// Namespace is the control-plane namespace of the shoot that ShootNodeCache belongs to. It is required
+	// to resolve a Node event to the single Worker resource hosted in that namespace.
+	Namespace string
+	// RecoverPanic defines whether panics occurring during the reconciliation should be recovered. It is
+	// passed through to ControllerOptions.RecoverPanic. A panic triggered by a single large Worker reconcile
+	// must not crash the whole manager.
+	RecoverPanic *bool
+	// PoolConcurrency is the number of machine-class/MachineDeployment operations the reconciler is allowed
+	// to run concurrently per worker pool, independent of MaxConcurrentReconciles. If zero or negative, pool
+	// operations are not limited.
+	PoolConcurrency int
 }
 
 // DefaultPredicates returns the default predicates for a Worker reconciler.
-func DefaultPredicates(ctx context.Context, mgr manager.Manager, ignoreOperationAnnotation bool) []predicate.Predicate {
-	return extensionspredicate.DefaultControllerPredicates(ignoreOperationAnnotation, extensionspredicate.ShootNotFailedPredicate(ctx, mgr))
+func DefaultPredicates(ctx context.Context, mgr manager.Manager, ignoreOperationAnnotation bool, extensionClass extensionsv1alpha1.ExtensionClass) []predicate.Predicate {
+	return extensionspredicate.DefaultControllerPredicates(
+		ignoreOperationAnnotation,
+		extensionspredicate.ExtensionClassPredicate(extensionClass),
+		extensionspredicate.ShootNotFailedPredicate(ctx, mgr),
+	)
 }
 
 // Add creates a new Worker Controller and adds it to the Manager.
 // and Start it when the Manager is Started.
 func Add(ctx context.Context, mgr manager.Manager, args AddArgs) error {
-	args.ControllerOptions.Reconciler = NewReconciler(mgr, args.Actuator)
-
-	predicates := extensionspredicate.AddTypePredicate(args.Predicates, args.Type)
-	if err := add(ctx, mgr, args, predicates); err != nil {
-		return err
-	}
-
-	return addStateUpdatingController(ctx, mgr, args.ControllerOptions, args.Type)
+	predicates := extensionspredicate.AddTypeAndClassPredicates(args.Predicates, args.ExtensionClass, args.Type)
+	return add(ctx, mgr, args, predicates)
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(ctx context.Context, mgr manager.Manager, args AddArgs, predicates []predicate.Predicate) error {
-	ctrl, err := controller.New(ControllerName, mgr, args.ControllerOptions)
-	if err != nil {
-		return err
-	}
+	args.ControllerOptions.RecoverPanic = args.RecoverPanic
+
+	ctrlBuilder := builder.TypedControllerManagedBy[reconcile.Request](mgr).
+		Named(ControllerName).
+		WithOptions(args.ControllerOptions).
+		WatchesRawSource(source.Kind[*extensionsv1alpha1.Worker](
+			mgr.GetCache(),
+			&extensionsv1alpha1.Worker{},
+			&handler.TypedEnqueueRequestForObject[*extensionsv1alpha1.Worker]{},
+			asTypedPredicates[*extensionsv1alpha1.Worker](predicates)...,
+		))
 
 	if args.IgnoreOperationAnnotation {
-		if err := ctrl.Watch(
-			&source.Kind{Type: &extensionsv1alpha1.Cluster{}},
-			mapper.EnqueueRequestsFrom(ctx, mgr.GetCache(), ClusterToWorkerMapper(ctx, mgr, predicates), mapper.UpdateWithNew, ctrl.GetLogger()),
-		); err != nil {
-			return err
-		}
+		ctrlBuilder = ctrlBuilder.WatchesRawSource(source.Kind[*extensionsv1alpha1.Cluster](
+			mgr.GetCache(),
+			&extensionsv1alpha1.Cluster{},
+			mapper.TypedEnqueueRequestsFrom[*extensionsv1alpha1.Cluster](ctx, mgr.GetCache(), ClusterToWorkerMapper(ctx, mgr, predicates), mapper.UpdateWithNew, mgr.GetLogger()),
+		))
 	}
 
-	return ctrl.Watch(&source.Kind{Type: &extensionsv1alpha1.Worker{}}, &handler.EnqueueRequestForObject{}, predicates...)
-}
+	if args.ShootNodeCache != nil {
+		ctrlBuilder = ctrlBuilder.WatchesRawSource(source.Kind[*corev1.Node](
+			args.ShootNodeCache,
+			&corev1.Node{},
+			handler.TypedEnqueueRequestsFromMapFunc(NodeToWorkerMapper(mgr.GetClient(), args.Namespace)),
+		))
+	}
 
-func addStateUpdatingController(ctx context.Context, mgr manager.Manager, options controller.Options, extensionType string) error {
-	var (
-		machinePredicates = []predicate.Predicate{
-			predicate.Or(
-				MachineNodeInfoHasChanged(),
-				predicate.GenerationChangedPredicate{},
-			),
-		}
-		workerPredicates = []predicate.Predicate{
-			extensionspredicate.HasType(extensionType),
-		}
-	)
+	return ctrlBuilder.Complete(NewReconciler(mgr, args.Actuator, args.PublishMachineDeploymentsEarly, args.ShootNodeCache, NewPoolLimiter(args.PoolConcurrency)))
+}
 
-	ctrl, err := controller.New(ControllerNameState, mgr, controller.Options{
-		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
-		Reconciler:              NewStateReconciler(mgr),
-	})
-	if err != nil {
-		return err
+// asTypedPredicates adapts untyped predicate.Predicates to predicate.TypedPredicate[T] so they can be passed
+// to a typed source.Kind[T] watch. It exists because the predicates built by extensionspredicate (and passed
+// in via AddArgs.Predicates) are untyped, while the Worker watch uses the generic controller-runtime v0.18
+// source/handler API.
+func asTypedPredicates[T client.Object](predicates []predicate.Predicate) []predicate.TypedPredicate[T] {
+	typed := make([]predicate.TypedPredicate[T], 0, len(predicates))
+	for _, p := range predicates {
+		typed = append(typed, asTypedPredicate[T](p))
 	}
+	return typed
+}
 
-	if err := ctrl.Watch(
-		&source.Kind{Type: &machinev1alpha1.MachineSet{}},
-		mapper.EnqueueRequestsFrom(ctx, mgr.GetCache(), MachineSetToWorkerMapper(workerPredicates), mapper.UpdateWithNew, ctrl.GetLogger()),
-		machinePredicates...,
-	); err != nil {
-		return err
+// asTypedPredicate adapts a single untyped predicate.Predicate to predicate.TypedPredicate[T] by converting
+// each typed event back to its untyped counterpart and delegating to p.
+func asTypedPredicate[T client.Object](p predicate.Predicate) predicate.TypedPredicate[T] {
+	return predicate.TypedFuncs[T]{
+		CreateFunc: func(e event.TypedCreateEvent[T]) bool {
+			return p.Create(event.CreateEvent{Object: e.Object})
+		},
+		UpdateFunc: func(e event.TypedUpdateEvent[T]) bool {
+			return p.Update(event.UpdateEvent{ObjectOld: e.ObjectOld, ObjectNew: e.ObjectNew})
+		},
+		DeleteFunc: func(e event.TypedDeleteEvent[T]) bool {
+			return p.Delete(event.DeleteEvent{Object: e.Object, DeleteStateUnknown: e.DeleteStateUnknown})
+		},
+		GenericFunc: func(e event.TypedGenericEvent[T]) bool {
+			return p.Generic(event.GenericEvent{Object: e.Object})
+		},
 	}
-
-	return ctrl.Watch(
-		&source.Kind{Type: &machinev1alpha1.Machine{}},
-		mapper.EnqueueRequestsFrom(ctx, mgr.GetCache(), MachineToWorkerMapper(workerPredicates), mapper.UpdateWithNew, ctrl.GetLogger()),
-		machinePredicates...,
-	)
 }
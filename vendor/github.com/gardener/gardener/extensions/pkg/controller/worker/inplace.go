@@ -0,0 +1,152 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+const (
+	// WorkerPoolLabel is the label on a shoot Node that references the worker pool it belongs to.
+	WorkerPoolLabel = "worker.gardener.cloud/pool"
+	// KubernetesVersionLabel is the label on a shoot Node that reports the kubelet's current Kubernetes
+	// version, as maintained by gardener-node-agent.
+	KubernetesVersionLabel = "worker.gardener.cloud/kubernetes-version"
+	// OSVersionLabel is the label on a shoot Node that reports the current OS (machine image) version, as
+	// maintained by gardener-node-agent.
+	OSVersionLabel = "worker.gardener.cloud/os-version"
+
+	// kubeletUnitName is the systemd unit restarted to roll out a new kubelet version in place.
+	kubeletUnitName = "kubelet.service"
+)
+
+// InPlaceActuator is implemented by Actuators that support updating a worker pool's OS version or kubelet
+// version in place, i.e. without rotating the underlying machines. It is consulted by the reconciler once a
+// Node watched via NodeToWorkerMapper reports a version that differs from the Worker pool's desired version.
+type InPlaceActuator interface {
+	// InPlaceUpdate performs the in-place update (kubelet and/or OS) of a single Node belonging to the given
+	// worker pool of the given Worker resource. The reconciler calls it once per Node that needs updating, so
+	// that pool-concurrency limiting bounds the actual number of concurrent node updates instead of the
+	// number of pools.
+	InPlaceUpdate(ctx context.Context, worker *extensionsv1alpha1.Worker, pool extensionsv1alpha1.WorkerPool, node *corev1.Node) error
+}
+
+// nodeInPlaceUpdate pairs a shoot Node that needs an in-place update with the worker pool it belongs to.
+type nodeInPlaceUpdate struct {
+	pool extensionsv1alpha1.WorkerPool
+	node *corev1.Node
+}
+
+// poolsNeedingInPlaceUpdate lists the shoot Nodes via shootNodeReader and returns, for every Node whose
+// reported Kubernetes or OS version (as labeled by gardener-node-agent) differs from the version desired for
+// its worker pool in worker.Spec, the pairing of that Node with its pool.
+func poolsNeedingInPlaceUpdate(ctx context.Context, shootNodeReader client.Reader, worker *extensionsv1alpha1.Worker) ([]nodeInPlaceUpdate, error) {
+	nodeList := &corev1.NodeList{}
+	if err := shootNodeReader.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed listing nodes: %w", err)
+	}
+
+	poolsByName := make(map[string]extensionsv1alpha1.WorkerPool, len(worker.Spec.Pools))
+	for _, pool := range worker.Spec.Pools {
+		poolsByName[pool.Name] = pool
+	}
+
+	var updates []nodeInPlaceUpdate
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+
+		pool, ok := poolsByName[node.Labels[WorkerPoolLabel]]
+		if !ok {
+			continue
+		}
+
+		kubernetesVersion, osVersion := node.Labels[KubernetesVersionLabel], node.Labels[OSVersionLabel]
+		if kubernetesVersion == "" || osVersion == "" {
+			// gardener-node-agent has not labeled this Node yet, e.g. because it is still bootstrapping.
+			// Treat it as up to date rather than comparing against an empty version and restarting its
+			// kubelet before it has even reported in.
+			continue
+		}
+
+		if kubernetesVersion != pool.KubernetesVersion || osVersion != pool.MachineImageVersion {
+			updates = append(updates, nodeInPlaceUpdate{pool: pool, node: node})
+		}
+	}
+
+	return updates, nil
+}
+
+// PerformInPlaceUpdate runs the in-place update sequence for a single worker pool: it first upgrades the OS
+// packages via executor, then restarts the kubelet via dbus so it picks up the new kubelet binary and
+// config. Actuator implementations call this from their InPlaceUpdate method; it is factored out so that
+// unit tests can assert the restart/upgrade sequence against fakes of executor and dbus without requiring
+// root privileges.
+func PerformInPlaceUpdate(ctx context.Context, executor CommandExecutor, dbus DBus, pool extensionsv1alpha1.WorkerPool) error {
+	if _, err := executor.RunCommand(ctx, "update-os-packages", "--pool", pool.Name, "--version", pool.MachineImageVersion); err != nil {
+		return fmt.Errorf("failed upgrading OS packages for pool %q: %w", pool.Name, err)
+	}
+
+	if err := dbus.Restart(ctx, kubeletUnitName); err != nil {
+		return fmt.Errorf("failed restarting kubelet for pool %q: %w", pool.Name, err)
+	}
+
+	return nil
+}
+
+// DBus is the subset of system-bus operations needed to restart the kubelet and other host units as part of
+// an in-place update. It mirrors the abstraction used by gardener-node-agent so that the same fake can be
+// reused in tests without requiring root privileges or a real system bus.
+type DBus interface {
+	// Restart restarts the given systemd unit.
+	Restart(ctx context.Context, unitName string) error
+}
+
+// CommandExecutor is the subset of os/exec operations needed to run host binaries (e.g. the OS package
+// manager) as part of an in-place update.
+type CommandExecutor interface {
+	// RunCommand runs the given command with the given arguments and returns its combined output.
+	RunCommand(ctx context.Context, command string, args ...string) ([]byte, error)
+}
+
+// FakeDBus is an in-memory DBus implementation for unit tests. It records every restarted unit instead of
+// talking to a real system bus.
+type FakeDBus struct {
+	RestartedUnits []string
+}
+
+// Restart records the unit as restarted.
+func (f *FakeDBus) Restart(_ context.Context, unitName string) error {
+	f.RestartedUnits = append(f.RestartedUnits, unitName)
+	return nil
+}
+
+// FakeCommandExecutor is an in-memory CommandExecutor implementation for unit tests. It records every
+// executed command instead of spawning a real process.
+type FakeCommandExecutor struct {
+	ExecutedCommands [][]string
+	Output           []byte
+}
+
+// RunCommand records the command and returns the configured fake output.
+func (f *FakeCommandExecutor) RunCommand(_ context.Context, command string, args ...string) ([]byte, error) {
+	f.ExecutedCommands = append(f.ExecutedCommands, append([]string{command}, args...))
+	return f.Output, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+var _ = Describe("PerformInPlaceUpdate", func() {
+	It("upgrades the OS packages and then restarts the kubelet", func() {
+		executor := &FakeCommandExecutor{}
+		dbus := &FakeDBus{}
+		pool := extensionsv1alpha1.WorkerPool{Name: "worker-pool", MachineImageVersion: "2.0.0"}
+
+		Expect(PerformInPlaceUpdate(context.Background(), executor, dbus, pool)).To(Succeed())
+
+		Expect(executor.ExecutedCommands).To(ConsistOf(
+			[]string{"update-os-packages", "--pool", "worker-pool", "--version", "2.0.0"},
+		))
+		Expect(dbus.RestartedUnits).To(ConsistOf(kubeletUnitName))
+	})
+})
+
+var _ = Describe("poolsNeedingInPlaceUpdate", func() {
+	It("returns only the nodes whose reported version differs from their pool's desired version", func() {
+		worker := &extensionsv1alpha1.Worker{
+			Spec: extensionsv1alpha1.WorkerSpec{
+				Pools: []extensionsv1alpha1.WorkerPool{
+					{Name: "up-to-date", KubernetesVersion: "1.30.1", MachineImageVersion: "2.0.0"},
+					{Name: "outdated", KubernetesVersion: "1.30.1", MachineImageVersion: "2.0.0"},
+				},
+			},
+		}
+
+		nodes := []client.Object{
+			nodeWithPoolLabels("node-1", "up-to-date", "1.30.1", "2.0.0"),
+			nodeWithPoolLabels("node-2", "outdated", "1.29.4", "2.0.0"),
+			nodeWithPoolLabels("node-3", "outdated", "", ""),
+		}
+		seedClient := fakeclient.NewClientBuilder().WithObjects(nodes...).Build()
+
+		updates, err := poolsNeedingInPlaceUpdate(context.Background(), seedClient, worker)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(updates).To(HaveLen(1))
+		Expect(updates[0].pool.Name).To(Equal("outdated"))
+		Expect(updates[0].node.Name).To(Equal("node-2"))
+	})
+
+	It("skips nodes that gardener-node-agent has not labeled with a version yet", func() {
+		worker := &extensionsv1alpha1.Worker{
+			Spec: extensionsv1alpha1.WorkerSpec{
+				Pools: []extensionsv1alpha1.WorkerPool{
+					{Name: "bootstrapping", KubernetesVersion: "1.30.1", MachineImageVersion: "2.0.0"},
+				},
+			},
+		}
+
+		// A Node that has only just joined the cluster carries the worker-pool label (set by MCM/the OS
+		// image) but not yet the version labels gardener-node-agent sets once it has started reporting.
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "bootstrapping-node",
+				Labels: map[string]string{WorkerPoolLabel: "bootstrapping"},
+			},
+		}
+		seedClient := fakeclient.NewClientBuilder().WithObjects(node).Build()
+
+		updates, err := poolsNeedingInPlaceUpdate(context.Background(), seedClient, worker)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updates).To(BeEmpty())
+	})
+})
+
+func nodeWithPoolLabels(name, pool, kubernetesVersion, osVersion string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				WorkerPoolLabel:        pool,
+				KubernetesVersionLabel: kubernetesVersion,
+				OSVersionLabel:         osVersion,
+			},
+		},
+	}
+}
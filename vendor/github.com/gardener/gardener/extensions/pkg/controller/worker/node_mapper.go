@@ -0,0 +1,63 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+// NodeToWorkerMapper returns a typed mapper that maps a Node in the shoot cluster to the Worker resource
+// owning the Machine that the Node is backed by. It is used to trigger a Worker reconciliation when
+// gardener-node-agent updates a Node's kubernetes-version or OS-version label, so that in-place updates of
+// the node's OS/kubelet can be driven without rotating the underlying machine.
+//
+// namespace is the control-plane namespace that seedClient's ShootNodeCache was set up for; since it hosts
+// exactly one Worker resource for a given extension type, the List calls below are scoped to it instead of
+// scanning across all namespaces in the seed.
+func NodeToWorkerMapper(seedClient client.Reader, namespace string) handler.TypedMapFunc[*corev1.Node] {
+	return func(ctx context.Context, node *corev1.Node) []reconcile.Request {
+		machineList := &machinev1alpha1.MachineList{}
+		if err := seedClient.List(ctx, machineList, client.InNamespace(namespace)); err != nil {
+			return nil
+		}
+
+		var machineFound bool
+		for _, machine := range machineList.Items {
+			if machine.Status.Node == node.Name {
+				machineFound = true
+				break
+			}
+		}
+		if !machineFound {
+			return nil
+		}
+
+		workerList := &extensionsv1alpha1.WorkerList{}
+		if err := seedClient.List(ctx, workerList, client.InNamespace(namespace)); err != nil || len(workerList.Items) == 0 {
+			return nil
+		}
+
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: workerList.Items[0].Name}}}
+	}
+}
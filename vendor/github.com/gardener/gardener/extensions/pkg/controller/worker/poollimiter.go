@@ -0,0 +1,71 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// PoolLimiter bounds how many machine-class/MachineDeployment operations the reconciler runs concurrently
+// for a given key, independent of the manager-wide MaxConcurrentReconciles and of any other key. Callers key
+// Acquire by both the owning Worker and the pool name (not the pool name alone), so that a large Worker with
+// many pools can fan its operations out across pools while still capping the work done within any single
+// pool, and so that two different Workers that happen to define a pool with the same name don't share a
+// concurrency budget.
+type PoolLimiter struct {
+	concurrency int
+
+	mu     sync.Mutex
+	tokens map[string]chan struct{}
+}
+
+// NewPoolLimiter returns a PoolLimiter that admits at most concurrency concurrent operations per key. A
+// non-positive concurrency disables limiting, i.e. every Acquire call returns immediately.
+func NewPoolLimiter(concurrency int) *PoolLimiter {
+	return &PoolLimiter{
+		concurrency: concurrency,
+		tokens:      make(map[string]chan struct{}),
+	}
+}
+
+// Acquire blocks until a slot for the given key is available or ctx is done, whichever happens first. The
+// returned release function must be called to free the slot again; it is a no-op if limiting is disabled.
+func (p *PoolLimiter) Acquire(ctx context.Context, key string) (release func(), err error) {
+	if p.concurrency <= 0 {
+		return func() {}, nil
+	}
+
+	tokens := p.tokensFor(key)
+
+	select {
+	case tokens <- struct{}{}:
+		return func() { <-tokens }, nil
+	case <-ctx.Done():
+		return func() {}, ctx.Err()
+	}
+}
+
+func (p *PoolLimiter) tokensFor(key string) chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tokens, ok := p.tokens[key]
+	if !ok {
+		tokens = make(chan struct{}, p.concurrency)
+		p.tokens[key] = tokens
+	}
+	return tokens
+}
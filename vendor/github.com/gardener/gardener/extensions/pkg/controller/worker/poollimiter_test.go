@@ -0,0 +1,61 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PoolLimiter", func() {
+	It("limits per key, not globally", func() {
+		limiter := NewPoolLimiter(1)
+		ctx := context.Background()
+
+		releaseA, err := limiter.Acquire(ctx, "shoot-a/pool")
+		Expect(err).NotTo(HaveOccurred())
+		defer releaseA()
+
+		// A concurrent acquire for a different key must not be blocked by shoot-a/pool's slot being held,
+		// even though the pool name component is identical; two different Workers must not share a budget.
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+
+			release, err := limiter.Acquire(ctx, "shoot-b/pool")
+			Expect(err).NotTo(HaveOccurred())
+			release()
+			close(done)
+		}()
+
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("blocks a second acquire within the same key until the first is released or the context is done", func() {
+		limiter := NewPoolLimiter(1)
+		ctxWithTimeout, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		release, err := limiter.Acquire(context.Background(), "shoot-a/pool")
+		Expect(err).NotTo(HaveOccurred())
+		defer release()
+
+		_, err = limiter.Acquire(ctxWithTimeout, "shoot-a/pool")
+		Expect(err).To(HaveOccurred())
+	})
+})
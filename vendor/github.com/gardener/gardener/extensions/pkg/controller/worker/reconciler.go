@@ -0,0 +1,215 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+// MachineDeploymentNamer is implemented by Actuators that can compute the name of the MachineDeployment(s)
+// they will create for a worker pool ahead of actually creating them, e.g. including the zone/hash suffixes
+// MCM adds. It is consulted by publishMachineDeploymentsStatus so that the MachineDeployment names written to
+// a Worker's status before the actuator runs match the names the actuator later creates, instead of a
+// guessed name that callers consuming status.MachineDeployments early (e.g. the cluster-autoscaler
+// deployment step) would key on in error.
+type MachineDeploymentNamer interface {
+	// MachineDeploymentName returns the name of the MachineDeployment resource that will be created for pool.
+	MachineDeploymentName(worker *extensionsv1alpha1.Worker, pool extensionsv1alpha1.WorkerPool) string
+}
+
+// reconciler reconciles Worker resources by delegating the actual machine orchestration to the configured
+// Actuator.
+type reconciler struct {
+	client                         client.Client
+	actuator                       Actuator
+	publishMachineDeploymentsEarly bool
+	// shootNodeReader reads Nodes from the shoot cluster that the reconciled Worker belongs to. It is nil
+	// unless the controller was set up with an AddArgs.ShootNodeCache, in which case in-place updates are
+	// not considered.
+	shootNodeReader client.Reader
+	// poolLimiter bounds how many in-place updates run concurrently for the same worker pool.
+	poolLimiter *PoolLimiter
+}
+
+// NewReconciler creates a new reconcile.Reconciler that reconciles Worker resources by delegating to the
+// given Actuator. If publishMachineDeploymentsEarly is true, the desired MachineDeployments are written to
+// the Worker's status before the reconciler waits for the underlying Machines to become Ready. If
+// shootNodeReader is non-nil and the Actuator implements InPlaceActuator, the reconciler drives in-place
+// updates for worker pools whose Nodes report an outdated Kubernetes or OS version, fanning the per-pool
+// updates out concurrently but bounded by poolLimiter.
+func NewReconciler(mgr manager.Manager, actuator Actuator, publishMachineDeploymentsEarly bool, shootNodeReader client.Reader, poolLimiter *PoolLimiter) reconcile.Reconciler {
+	return &reconciler{
+		client:                         mgr.GetClient(),
+		actuator:                       actuator,
+		publishMachineDeploymentsEarly: publishMachineDeploymentsEarly,
+		shootNodeReader:                shootNodeReader,
+		poolLimiter:                    poolLimiter,
+	}
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	worker := &extensionsv1alpha1.Worker{}
+	if err := r.client.Get(ctx, request.NamespacedName, worker); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if r.publishMachineDeploymentsEarly {
+		if err := r.publishMachineDeploymentsStatus(ctx, worker); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed publishing machine deployments early: %w", err)
+		}
+	}
+
+	if inPlaceActuator, ok := r.actuator.(InPlaceActuator); ok && r.shootNodeReader != nil {
+		if err := r.reconcileInPlaceUpdates(ctx, inPlaceActuator, worker); err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed reconciling in-place worker pool updates: %w", err)
+		}
+	}
+
+	return r.actuator.Reconcile(ctx, worker)
+}
+
+// reconcileInPlaceUpdates determines the individual shoot Nodes whose reported Kubernetes or OS version is
+// outdated and drives an in-place update for each of them via inPlaceActuator. The per-node updates run
+// concurrently; r.poolLimiter bounds how many run at once for a given Worker/pool pair, so a pool with many
+// Nodes does not update all of them at once, while unrelated pools (of this or any other Worker) are not
+// serialized behind it.
+func (r *reconciler) reconcileInPlaceUpdates(ctx context.Context, inPlaceActuator InPlaceActuator, worker *extensionsv1alpha1.Worker) error {
+	updates, err := poolsNeedingInPlaceUpdate(ctx, r.shootNodeReader, worker)
+	if err != nil {
+		return fmt.Errorf("failed determining nodes needing an in-place update: %w", err)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for _, update := range updates {
+		wg.Add(1)
+		go func(update nodeInPlaceUpdate) {
+			defer wg.Done()
+
+			err := r.runInPlaceUpdateNode(ctx, inPlaceActuator, worker, update.pool, update.node)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(update)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// runInPlaceUpdateNode calls inPlaceUpdateNode and recovers from any panic raised while doing so, turning it
+// into an error. It runs on its own goroutine per Node, so an unrecovered panic here would otherwise
+// propagate past this goroutine's stack and crash the whole manager process, taking every other Worker being
+// reconciled down with it.
+func (r *reconciler) runInPlaceUpdateNode(ctx context.Context, inPlaceActuator InPlaceActuator, worker *extensionsv1alpha1.Worker, pool extensionsv1alpha1.WorkerPool, node *corev1.Node) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic during in-place update of node %q in pool %q: %v", node.Name, pool.Name, recovered)
+		}
+	}()
+
+	return r.inPlaceUpdateNode(ctx, inPlaceActuator, worker, pool, node)
+}
+
+// inPlaceUpdateNode acquires a slot from r.poolLimiter, keyed by the owning Worker and pool, before
+// delegating the in-place update of node to inPlaceActuator. Keying by Worker in addition to pool name
+// ensures that two different Workers which happen to define a pool with the same name (e.g. "worker") don't
+// contend for the same concurrency budget.
+func (r *reconciler) inPlaceUpdateNode(ctx context.Context, inPlaceActuator InPlaceActuator, worker *extensionsv1alpha1.Worker, pool extensionsv1alpha1.WorkerPool, node *corev1.Node) error {
+	release, err := r.poolLimiter.Acquire(ctx, fmt.Sprintf("%s/%s/%s", worker.Namespace, worker.Name, pool.Name))
+	if err != nil {
+		return fmt.Errorf("failed acquiring pool concurrency slot for pool %q: %w", pool.Name, err)
+	}
+	defer release()
+
+	if err := inPlaceActuator.InPlaceUpdate(ctx, worker, pool, node); err != nil {
+		return fmt.Errorf("failed in-place update of node %q in pool %q: %w", node.Name, pool.Name, err)
+	}
+
+	return nil
+}
+
+// publishMachineDeploymentsStatus writes a MachineDeployment entry (name, min, max, priority) into
+// worker.status for every worker pool that does not have one yet, together with a
+// MachineDeploymentsLastUpdateTime. It runs before the actuator reconciles the Machines/MachineSets to
+// Ready, so that consumers such as gardenlet's cluster-autoscaler deployment step can start as soon as the
+// MachineDeployments are known, instead of waiting for the Worker to become Ready.
+//
+// It only fills in pools that are still missing from worker.Status.MachineDeployments; pools the actuator has
+// already published (possibly as several zone-sharded MachineDeployments per pool) are left untouched, so
+// this early publish never clobbers the richer list the actuator produces once it runs. If the Actuator does
+// not implement MachineDeploymentNamer, publishing is skipped entirely rather than guessing a name that would
+// not match the one the actuator actually creates.
+func (r *reconciler) publishMachineDeploymentsStatus(ctx context.Context, worker *extensionsv1alpha1.Worker) error {
+	namer, ok := r.actuator.(MachineDeploymentNamer)
+	if !ok {
+		return nil
+	}
+
+	knownPools := make(map[string]bool, len(worker.Status.MachineDeployments))
+	for _, machineDeployment := range worker.Status.MachineDeployments {
+		knownPools[machineDeployment.PoolName] = true
+	}
+
+	machineDeployments := append([]extensionsv1alpha1.MachineDeployment{}, worker.Status.MachineDeployments...)
+	var changed bool
+	for _, pool := range worker.Spec.Pools {
+		if knownPools[pool.Name] {
+			continue
+		}
+
+		changed = true
+		machineDeployments = append(machineDeployments, extensionsv1alpha1.MachineDeployment{
+			Name:     namer.MachineDeploymentName(worker, pool),
+			PoolName: pool.Name,
+			Minimum:  pool.Minimum,
+			Maximum:  pool.Maximum,
+			Priority: pool.Priority,
+		})
+	}
+
+	if !changed {
+		return nil
+	}
+
+	patch := client.MergeFrom(worker.DeepCopy())
+	now := metav1.Now()
+	worker.Status.MachineDeployments = machineDeployments
+	worker.Status.MachineDeploymentsLastUpdateTime = &now
+	return r.client.Status().Patch(ctx, worker, patch)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Worker is a specification for a Worker resource.
+type Worker struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec contains the specification of this Worker resource.
+	Spec WorkerSpec `json:"spec"`
+	// Status contains the status of this Worker resource.
+	// +optional
+	Status WorkerStatus `json:"status,omitempty"`
+}
+
+// WorkerList is a list of Worker resources.
+type WorkerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of Worker resources.
+	Items []Worker `json:"items"`
+}
+
+// WorkerSpec is the spec for a Worker resource.
+type WorkerSpec struct {
+	// Pools is a list of worker pools.
+	// +optional
+	Pools []WorkerPool `json:"pools,omitempty"`
+}
+
+// WorkerStatus is the status for a Worker resource.
+type WorkerStatus struct {
+	// DefaultStatus is a structure containing common fields used by all extension resources.
+	DefaultStatus `json:",inline"`
+	// MachineDeployments is a list of created machine deployments. It is needed for the autoscaler to
+	// identify the machines and the corresponding nodes.
+	// +optional
+	MachineDeployments []MachineDeployment `json:"machineDeployments,omitempty"`
+	// MachineDeploymentsLastUpdateTime is the timestamp when the status.MachineDeployments slice was last
+	// updated. Consumers that only care about the MachineDeployments (e.g. the cluster-autoscaler
+	// deployment step) can wait on this timestamp instead of on the Worker becoming Ready.
+	// +optional
+	MachineDeploymentsLastUpdateTime *metav1.Time `json:"machineDeploymentsLastUpdateTime,omitempty"`
+}
+
+// WorkerPool is the definition of a specific worker pool.
+type WorkerPool struct {
+	// Name is the name of this worker pool.
+	Name string `json:"name"`
+	// Minimum is the minimum number of machines to create for this worker pool.
+	Minimum int32 `json:"minimum"`
+	// Maximum is the maximum number of machines to create for this worker pool.
+	Maximum int32 `json:"maximum"`
+	// Priority is the priority of this worker pool for scale-down decisions.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+	// KubernetesVersion is the desired Kubernetes version for this worker pool.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// MachineImageVersion is the desired machine image version for this worker pool.
+	MachineImageVersion string `json:"machineImageVersion,omitempty"`
+}
+
+// MachineDeployment is a created machine deployment.
+type MachineDeployment struct {
+	// Name is the name of the MachineDeployment resource.
+	Name string `json:"name"`
+	// Minimum is the minimum value for the machine deployment.
+	Minimum int32 `json:"minimum"`
+	// Maximum is the maximum value for the machine deployment.
+	Maximum int32 `json:"maximum"`
+	// PoolName is the name of the worker pool to which the machine deployment belongs.
+	PoolName string `json:"poolName,omitempty"`
+	// Priority is the priority for the machine deployment.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+}
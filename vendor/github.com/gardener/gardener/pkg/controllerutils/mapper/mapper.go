@@ -0,0 +1,88 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mapper provides helpers for turning a Mapper (which maps one object to the reconcile.Requests of
+// other objects it affects) into a controller-runtime event handler.
+package mapper
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// UpdateStrategy determines which object revision(s) a Mapper is given for update events.
+type UpdateStrategy byte
+
+const (
+	// UpdateWithOldAndNew passes both the old and the new object revision to the Mapper.
+	UpdateWithOldAndNew UpdateStrategy = iota
+	// UpdateWithNew passes only the new object revision to the Mapper.
+	UpdateWithNew
+)
+
+// Mapper maps an object to the reconcile.Requests of the objects it affects.
+type Mapper interface {
+	Map(ctx context.Context, log logr.Logger, reader client.Reader, obj client.Object) []reconcile.Request
+}
+
+// MapperFunc is a func that implements Mapper.
+type MapperFunc func(ctx context.Context, log logr.Logger, reader client.Reader, obj client.Object) []reconcile.Request
+
+// Map implements Mapper.
+func (f MapperFunc) Map(ctx context.Context, log logr.Logger, reader client.Reader, obj client.Object) []reconcile.Request {
+	return f(ctx, log, reader, obj)
+}
+
+// EnqueueRequestsFrom creates a handler.EventHandler that enqueues the reconcile.Requests returned by m for
+// the object reported by an event. Deprecated: prefer TypedEnqueueRequestsFrom together with the generic
+// source.Kind[T] API introduced in controller-runtime v0.18.
+func EnqueueRequestsFrom(ctx context.Context, reader client.Reader, m Mapper, updateStrategy UpdateStrategy, log logr.Logger) handler.EventHandler {
+	return TypedEnqueueRequestsFrom[client.Object](ctx, reader, m, updateStrategy, log)
+}
+
+// TypedEnqueueRequestsFrom creates a handler.TypedEventHandler for objects of type T that enqueues the
+// reconcile.Requests returned by m for the object reported by an event. It is parameterized over the object
+// type only; the request type is fixed to reconcile.Request, matching every caller in this tree (all of which
+// go through builder.TypedControllerManagedBy[reconcile.Request]).
+func TypedEnqueueRequestsFrom[T client.Object](ctx context.Context, reader client.Reader, m Mapper, updateStrategy UpdateStrategy, log logr.Logger) handler.TypedEventHandler[T, reconcile.Request] {
+	mapAndEnqueue := func(obj T, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		for _, req := range m.Map(ctx, log, reader, obj) {
+			q.Add(req)
+		}
+	}
+
+	return &handler.TypedFuncs[T, reconcile.Request]{
+		CreateFunc: func(_ context.Context, e event.TypedCreateEvent[T], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			mapAndEnqueue(e.Object, q)
+		},
+		UpdateFunc: func(_ context.Context, e event.TypedUpdateEvent[T], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			if updateStrategy == UpdateWithOldAndNew {
+				mapAndEnqueue(e.ObjectOld, q)
+			}
+			mapAndEnqueue(e.ObjectNew, q)
+		},
+		DeleteFunc: func(_ context.Context, e event.TypedDeleteEvent[T], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			mapAndEnqueue(e.Object, q)
+		},
+		GenericFunc: func(_ context.Context, e event.TypedGenericEvent[T], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			mapAndEnqueue(e.Object, q)
+		},
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shootstate provides helpers for computing the parts of a Shoot's persisted state that are
+// derived from machine-controller-manager resources. It is shared between gardenlet, which persists
+// this state as part of ShootState.Deploy, and extension controllers that still reconcile it themselves.
+package shootstate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MachineDeploymentState represents the last known state of a MachineDeployment.
+type MachineDeploymentState struct {
+	// Replicas is the last known replica count of the MachineDeployment.
+	Replicas int32 `json:"replicas"`
+	// MachineSets is the last known state of the MachineSets owned by the MachineDeployment.
+	MachineSets []machinev1alpha1.MachineSet `json:"machineSets,omitempty"`
+	// Machines is the last known state of the Machines owned by the MachineDeployment's MachineSets.
+	Machines []machinev1alpha1.Machine `json:"machines,omitempty"`
+}
+
+// ComputeMachineState lists the MachineDeployments, MachineSets and Machines in the given namespace and
+// computes their combined, JSON-marshalled state, keyed by MachineDeployment name. Callers (gardenlet as
+// part of ShootState.Deploy, or extension controllers that still persist the Worker state themselves) are
+// expected to store the returned bytes verbatim.
+func ComputeMachineState(ctx context.Context, cl client.Client, namespace string) ([]byte, error) {
+	machineDeployments := &machinev1alpha1.MachineDeploymentList{}
+	if err := cl.List(ctx, machineDeployments, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed listing machine deployments: %w", err)
+	}
+
+	machineSets := &machinev1alpha1.MachineSetList{}
+	if err := cl.List(ctx, machineSets, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed listing machine sets: %w", err)
+	}
+
+	machines := &machinev1alpha1.MachineList{}
+	if err := cl.List(ctx, machines, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed listing machines: %w", err)
+	}
+
+	ownerToMachineSets := BuildOwnerToMachineSetsMap(machineSets.Items)
+	ownerToMachines := BuildOwnerToMachinesMap(machines.Items)
+
+	state := make(map[string]MachineDeploymentState, len(machineDeployments.Items))
+	for _, machineDeployment := range machineDeployments.Items {
+		deploymentState := MachineDeploymentState{
+			Replicas:    machineDeployment.Spec.Replicas,
+			MachineSets: ownerToMachineSets[machineDeployment.Name],
+		}
+
+		for _, machineSet := range deploymentState.MachineSets {
+			deploymentState.Machines = append(deploymentState.Machines, ownerToMachines[machineSet.Name]...)
+		}
+
+		state[machineDeployment.Name] = deploymentState
+	}
+
+	return json.Marshal(state)
+}
+
+// BuildOwnerToMachineSetsMap returns a map from the name of the MachineDeployment owning a MachineSet to
+// the list of MachineSets it owns.
+func BuildOwnerToMachineSetsMap(machineSets []machinev1alpha1.MachineSet) map[string][]machinev1alpha1.MachineSet {
+	ownerToMachineSets := make(map[string][]machinev1alpha1.MachineSet)
+	for _, machineSet := range machineSets {
+		for _, ownerReference := range machineSet.OwnerReferences {
+			if ownerReference.Kind == "MachineDeployment" {
+				ownerToMachineSets[ownerReference.Name] = append(ownerToMachineSets[ownerReference.Name], machineSet)
+			}
+		}
+	}
+	return ownerToMachineSets
+}
+
+// BuildOwnerToMachinesMap returns a map from the name of the MachineSet owning a Machine to the list of
+// Machines it owns.
+func BuildOwnerToMachinesMap(machines []machinev1alpha1.Machine) map[string][]machinev1alpha1.Machine {
+	ownerToMachines := make(map[string][]machinev1alpha1.Machine)
+	for _, machine := range machines {
+		for _, ownerReference := range machine.OwnerReferences {
+			if ownerReference.Kind == "MachineSet" {
+				ownerToMachines[ownerReference.Name] = append(ownerToMachines[ownerReference.Name], machine)
+			}
+		}
+	}
+	return ownerToMachines
+}